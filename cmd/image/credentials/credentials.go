@@ -0,0 +1,142 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package credentials implements `pvsadm image credentials`, which audits and cleans up the
+// COS HMAC service credentials that `pvsadm image import` auto-provisions, so long-running
+// accounts don't quietly accumulate one credential per import.
+package credentials
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg"
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+	"github.com/ppc64le-cloud/pvsadm/pkg/image/source"
+	"github.com/ppc64le-cloud/pvsadm/pkg/utils"
+)
+
+var (
+	bucketName   string
+	bucketRegion string
+)
+
+var Cmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Manage the COS service credentials pvsadm auto-provisions for image import",
+	Long: `Manage the COS service credentials pvsadm auto-provisions for image import.
+
+pvsadm image import auto-creates an HMAC-enabled service credential named
+"pvsadm-service-cred-<COS instance>" the first time it needs one. These subcommands enumerate,
+delete, or rotate the credentials pvsadm has provisioned for a given bucket's COS instance,
+identified by that name prefix.
+`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return utils.EnsurePrerequisitesAreSet(pkg.Options.APIKey, bucketName, bucketRegion)
+	},
+}
+
+func init() {
+	Cmd.PersistentFlags().StringVarP(&bucketName, "bucket", "b", "", "Cloud Object Storage bucket name.")
+	Cmd.PersistentFlags().StringVarP(&bucketRegion, "bucket-region", "r", "", "Cloud Object Storage bucket location.")
+	_ = Cmd.MarkPersistentFlagRequired("bucket")
+	_ = Cmd.MarkPersistentFlagRequired("bucket-region")
+
+	Cmd.AddCommand(listCmd, pruneCmd, rotateCmd)
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the pvsadm-managed service credentials on the bucket's COS instance",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, managed, err := resolveManaged()
+		if err != nil {
+			return err
+		}
+		if len(managed) == 0 {
+			fmt.Println("no pvsadm-managed service credentials found")
+			return nil
+		}
+		fmt.Printf("%-40s%-50s%s\n", "ID", "NAME", "ACCESS KEY ID")
+		for _, mc := range managed {
+			fmt.Printf("%-40s%-50s%s\n", mc.ID, mc.Name, mc.AccessKeyID)
+		}
+		return nil
+	},
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete every pvsadm-managed service credential on the bucket's COS instance",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pvsClient, managed, err := resolveManaged()
+		if err != nil {
+			return err
+		}
+		for _, mc := range managed {
+			klog.Infof("Deleting service credential %q", mc.Name)
+			if err := source.DeleteManagedCredential(pvsClient, mc.ID); err != nil {
+				return err
+			}
+		}
+		klog.Infof("Deleted %d service credential(s)", len(managed))
+		return nil
+	},
+}
+
+var rotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Delete every pvsadm-managed service credential and provision a fresh one",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pvsClient, err := client.NewClientWithEnv(pkg.Options.APIKey, pkg.Options.Environment, pkg.Options.Debug)
+		if err != nil {
+			return err
+		}
+
+		// ResolveDestinationCredentials, given an empty AccessKey/SecretKey and
+		// CredentialPolicy "rotate", deletes every pvsadm-managed credential on the instance
+		// and provisions a fresh one, which is exactly this subcommand's job.
+		accessKey, _, err := source.ResolveDestinationCredentials(source.Options{
+			PVSClient:        pvsClient,
+			Region:           bucketRegion,
+			BucketName:       bucketName,
+			CredentialPolicy: "rotate",
+		})
+		if err != nil {
+			return err
+		}
+		klog.Infof("Provisioned new service credential with access key %s", accessKey)
+		return nil
+	},
+}
+
+// resolveManaged authenticates and returns the pvsadm-managed credentials on bucketName's
+// COS instance.
+func resolveManaged() (*client.Client, []source.ManagedCredential, error) {
+	pvsClient, err := client.NewClientWithEnv(pkg.Options.APIKey, pkg.Options.Environment, pkg.Options.Debug)
+	if err != nil {
+		return nil, nil, err
+	}
+	cosInstance, err := source.FindCOSInstance(pvsClient, bucketName, bucketRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+	managed, err := source.ListManagedCredentials(pvsClient, cosInstance)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pvsClient, managed, nil
+}
@@ -15,99 +15,52 @@
 package _import
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"strings"
+	"os"
 	"time"
 
-	pmodels "github.com/IBM-Cloud/power-go-client/power/models"
-	"github.com/IBM/platform-services-go-sdk/resourcecontrollerv2"
 	"github.com/spf13/cobra"
-	"k8s.io/klog/v2"
-	"k8s.io/utils/ptr"
 
 	"github.com/ppc64le-cloud/pvsadm/pkg"
 	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+	"github.com/ppc64le-cloud/pvsadm/pkg/image/importer"
+	"github.com/ppc64le-cloud/pvsadm/pkg/image/source"
 	"github.com/ppc64le-cloud/pvsadm/pkg/utils"
 )
 
-const (
-	accessKeyId          = "access_key_id"
-	cosHmacKeys          = "cos_hmac_keys"
-	crnServiceRoleWriter = "crn:v1:bluemix:public:iam::::serviceRole:Writer"
-	imageStateActive     = "active"
-	jobStateCompleted    = "completed"
-	jobStateFailed       = "failed"
-	secretAccessKey      = "secret_access_key"
-	// CosResourceID is IBM COS service id, can be retrieved using ibmcloud cli
-	// ibmcloud catalog service cloud-object-storage.
-	serviceCredPrefix = "pvsadm-service-cred"
-)
+var (
+	checksumFlag string
+	manifestFlag string
 
-// findCOSInstance retrieves the service instance in which the bucket is present.
-func findCOSInstanceDetails(resources []resourcecontrollerv2.ResourceInstance, pvsClient *client.Client) *resourcecontrollerv2.ResourceInstance {
-	for _, resource := range resources {
-		s3client, err := client.NewS3Client(pvsClient, *resource.Name, pkg.ImageCMDOptions.Region)
-		if err != nil {
-			klog.Warningf("cannot create a new s3 client. err: %v", err)
-			continue
-		}
-		buckets, err := s3client.S3Session.ListBuckets(nil)
-		if err != nil {
-			klog.Warningf("cannot list buckets in the resource instance. err: %v", err)
-			continue
-		}
-		for _, bucket := range buckets.Buckets {
-			if *bucket.Name == pkg.ImageCMDOptions.BucketName {
-				return &resource
-			}
-		}
-	}
-	return nil
-}
+	sourceTypeFlag string
 
-// createNewCredentialsWithHMAC generates the service credentials in the given COS instance with HMAC keys.
-func createNewCredentialsWithHMAC(pvsClient *client.Client, cosCRN, serviceCredName string) (*resourcecontrollerv2.ResourceKey, error) {
-	klog.V(2).Infof("Auto generating COS service credentials to import image: %s", serviceCredName)
-	params := &resourcecontrollerv2.ResourceKeyPostParameters{}
-	params.SetProperty("HMAC", true)
-	key, _, err := pvsClient.ResourceControllerClient.CreateResourceKey(
-		&resourcecontrollerv2.CreateResourceKeyOptions{
-			Name:       ptr.To(serviceCredName),
-			Parameters: params,
-			Role:       ptr.To(crnServiceRoleWriter),
-			Source:     ptr.To(cosCRN),
-		},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("unable to create resource key for service instance: %v", err.Error())
-	}
-	return key, nil
-}
+	s3Endpoint     string
+	s3SourceBucket string
+	s3SourceObject string
+	s3AccessKey    string
+	s3SecretKey    string
 
-// checkStorageTierAvailability confirms if the provided cloud instance ID supports the required storageType.
-func checkStorageTierAvailability(pvsClient *client.PVMClient, storageType string) error {
-	// Supported tiers are Tier0, Tier1, Tier3 and Tier 5k
-	// The use of fixed IOPS is limited to volumes with a size of 200 GB or less, which is the break even size with Tier 0
-	// (200 GB @ 25 IOPS/GB = 5000 IOPS).
-	// Ref: https://cloud.ibm.com/docs/power-iaas?topic=power-iaas-on-cloud-architecture#storage-tiers
-	// API Docs for Storagetypes: https://cloud.ibm.com/docs/power-iaas?topic=power-iaas-on-cloud-architecture#IOPS-api
-
-	validStorageType := []string{"tier3", "tier1", "tier0", "tier5k"}
-	if !utils.Contains(validStorageType, storageType) {
-		return fmt.Errorf("provide valid StorageType. Allowable values are %v", validStorageType)
-	}
+	gcsAccessKey    string
+	gcsSecretKey    string
+	gcsSourceBucket string
+	gcsSourceObject string
 
-	storageTiers, err := pvsClient.StorageTierClient.GetAll()
-	if err != nil {
-		return fmt.Errorf("an error occured while retriving the Storage tier availability. err:%v", err)
-	}
-	for _, storageTier := range storageTiers {
-		if storageTier.Name == storageType && *storageTier.State == "inactive" {
-			return fmt.Errorf("the requested storage tier is not available in the provided cloud instance. Please retry with a different tier")
-		}
-	}
-	return nil
-}
+	azureSASURL string
+	sourceURL   string
+
+	outputFlag string
+
+	workspaceNames []string
+	workspaceIDs   []string
+	parallelism    int
+
+	imageSizeGB float64
+
+	credentialPolicy          string
+	deleteCredentialOnSuccess bool
+)
 
 var Cmd = &cobra.Command{
 	Use:   "import",
@@ -138,15 +91,54 @@ pvsadm image import -n upstream-core-lon04 -b <BUCKETNAME> --object rhel-83-1003
 
 # import image from a public IBM Cloud Storage bucket
 pvsadm image import -n upstream-core-lon04 -b <BUCKETNAME> --object rhel-83-10032020.ova.gz --pvs-image-name test-image -r <REGION> --public-bucket
+
+# verify the object checksum before importing
+pvsadm image import -n upstream-core-lon04 -b <BUCKETNAME> --object rhel-83-10032020.ova.gz --pvs-image-name test-image -r <REGION> --checksum sha256:<HEX>
+
+# import a batch of images described in a manifest, skipping entries already completed in a previous run
+pvsadm image import -n upstream-core-lon04 -b <BUCKETNAME> -r <REGION> --manifest ./manifest.yaml
+
+# fan the same object out to several workspaces at once, at most four imports in flight at a time
+pvsadm image import --workspace-name ws-a --workspace-name ws-b --workspace-name ws-c -b <BUCKETNAME> --object rhel-83-10032020.ova.gz --pvs-image-name test-image -r <REGION> --parallelism 4
 `,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		// ensure that both, the AccessKey and SecretKey are either both set or unset
 		if (len(pkg.ImageCMDOptions.AccessKey) > 0) != (len(pkg.ImageCMDOptions.SecretKey) > 0) {
 			return fmt.Errorf("required both --accesskey and --secretkey values")
 		}
-		return utils.EnsurePrerequisitesAreSet(pkg.Options.APIKey, pkg.ImageCMDOptions.WorkspaceID, pkg.ImageCMDOptions.WorkspaceName)
+		// --object and --pvs-image-name describe a single import and are mutually exclusive
+		// with --manifest, which carries one or more of them instead.
+		if manifestFlag == "" {
+			if pkg.ImageCMDOptions.ImageFilename == "" || pkg.ImageCMDOptions.ImageName == "" {
+				return fmt.Errorf("required both --object and --pvs-image-name values, or --manifest")
+			}
+		} else if pkg.ImageCMDOptions.ImageFilename != "" || pkg.ImageCMDOptions.ImageName != "" {
+			return fmt.Errorf("--manifest cannot be combined with --object or --pvs-image-name")
+		}
+		if outputFlag != "text" && outputFlag != "json" {
+			return fmt.Errorf("--output must be one of [text, json], got %q", outputFlag)
+		}
+		if parallelism < 1 {
+			return fmt.Errorf("--parallelism must be at least 1")
+		}
+		switch credentialPolicy {
+		case "reuse", "create", "rotate":
+		default:
+			return fmt.Errorf("--credential-policy must be one of [reuse, create, rotate], got %q", credentialPolicy)
+		}
+		if selectors := workspaceSelectors(); len(selectors) > 1 {
+			if deleteCredentialOnSuccess {
+				return fmt.Errorf("--delete-credential-on-success cannot be combined with multiple --workspace-name/--workspace-id values: concurrent workers importing into the same bucket commonly share one resolved credential, so the first worker to finish would delete it out from under the others")
+			}
+		} else if len(selectors) == 0 {
+			return utils.EnsurePrerequisitesAreSet(pkg.Options.APIKey, pkg.ImageCMDOptions.WorkspaceID, pkg.ImageCMDOptions.WorkspaceName)
+		}
+		return utils.EnsurePrerequisitesAreSet(pkg.Options.APIKey)
 	},
 
+	// RunE is a thin wrapper: it builds the importer.ImportOptions this invocation describes
+	// and hands the workflow off to pkg/image/importer, which does the actual work and is
+	// also usable as a library outside this CLI.
 	RunE: func(cmd *cobra.Command, args []string) error {
 		opt := pkg.ImageCMDOptions
 
@@ -155,160 +147,107 @@ pvsadm image import -n upstream-core-lon04 -b <BUCKETNAME> --object rhel-83-1003
 			return err
 		}
 
-		pvmclient, err := client.NewPVMClientWithEnv(pvsClient, opt.WorkspaceID, opt.WorkspaceName, pkg.Options.Environment)
-		if err != nil {
-			return err
-		}
-
-		if err := checkStorageTierAvailability(pvmclient, opt.StorageType); err != nil {
-			return err
-		}
-
-		//Create AccessKey and SecretKey for the bucket provided if bucket access is private
-		if (opt.AccessKey == "" || opt.SecretKey == "") && (!opt.Public) {
-			// Find COS instance of the bucket
-			listServiceInstanceOptions := &resourcecontrollerv2.ListResourceInstancesOptions{
-				ResourceID: ptr.To(utils.CosResourceID),
-			}
-
-			workspaces, _, err := pvsClient.ResourceControllerClient.ListResourceInstances(listServiceInstanceOptions)
-			if err != nil {
-				return fmt.Errorf("failed to list the resource instances: %v", err)
+		selectors := workspaceSelectors()
+		if len(selectors) > 1 {
+			if manifestFlag != "" {
+				return fmt.Errorf("--manifest cannot be combined with multiple --workspace-name/--workspace-id values")
 			}
-			if len(workspaces.Resources) == 0 {
-				return fmt.Errorf("no service instances were found")
-			}
-
-			cosInstance := findCOSInstanceDetails(workspaces.Resources, pvsClient)
-			if cosInstance == nil {
-				return fmt.Errorf("failed to find the COS instance for the bucket mentioned: %s", opt.BucketName)
-			}
-
-			klog.Infof("Identified bucket %q in service instance: %s", opt.BucketName, *cosInstance.Name)
-			listResourceKeysInstanceOptions := &resourcecontrollerv2.ListResourceKeysForInstanceOptions{
-				ID: cosInstance.GUID,
-			}
-			keys, _, err := pvsClient.ResourceControllerClient.ListResourceKeysForInstance(listResourceKeysInstanceOptions)
-			if err != nil {
-				return fmt.Errorf("cannot list the resource keys for instance. err: %v", err)
-			}
-
-			var ok, credentialsPresent bool
-			var hmacKeys map[string]interface{}
-			var key *resourcecontrollerv2.ResourceKey
-
-			if opt.ServiceCredName == "" {
-				opt.ServiceCredName = serviceCredPrefix + "-" + *cosInstance.Name
-			}
-
-			// Create the service credential if does not exist
-			if len(keys.Resources) == 0 {
-				if key, err = createNewCredentialsWithHMAC(pvsClient, *cosInstance.CRN, opt.ServiceCredName); err != nil {
-					return fmt.Errorf("error while creating HMAC credentials. err: %v", err)
-				}
-			} else {
-				klog.V(2).Info("Reading the existing service credential")
-				// Use the service credential already created. There may be a possibility that multiple credentials exist, but the HMAC credentials may not be present.
-				// In such case, manually re-create the credentials.
-
-				for _, serviceCredential := range keys.Resources {
-					key, _, err = pvsClient.ResourceControllerClient.GetResourceKey(
-						&resourcecontrollerv2.GetResourceKeyOptions{
-							ID: serviceCredential.ID,
-						},
-					)
-					if err != nil {
-						return fmt.Errorf("an error occured while retriving the resource key. err: %v", err)
-					}
-					// if the current credential has COS HMAC keys, reuse the same for importing the image
-					if prop := key.Credentials.GetProperty(cosHmacKeys); prop != nil {
-						klog.Infof("HMAC keys are available from the credential %q, re-using the same for image upload", *key.Name)
-						credentialsPresent = true
-						break
-					}
-					klog.Infof("No credentials found in the key %q.", *key.Name)
-				}
-				// if all the available service credentials do not have HMAC, create one with HMAC.
-				if !credentialsPresent {
-					if key, err = createNewCredentialsWithHMAC(pvsClient, *cosInstance.CRN, opt.ServiceCredName); err != nil {
-						return fmt.Errorf("error while creating HMAC credentials. err: %v", err)
-					}
-				}
-			}
-
-			prop := key.Credentials.GetProperty(cosHmacKeys)
-			if prop == nil {
-				return fmt.Errorf("unable to retrieve COS HMAC keys")
-			}
-
-			if hmacKeys, ok = prop.(map[string]interface{}); !ok {
-				return fmt.Errorf("type assertion for HMAC keys failed")
-			}
-			// Assign the Access Key and Secret Key for further operation
-			opt.AccessKey = hmacKeys[accessKeyId].(string)
-			opt.SecretKey = hmacKeys[secretAccessKey].(string)
+			return runParallel(pvsClient, opt, selectors, parallelism)
 		}
 
-		//By default Bucket Access is private
-		bucketAccess := "private"
-
-		if opt.Public {
-			bucketAccess = "public"
+		workspaceID, workspaceName := opt.WorkspaceID, opt.WorkspaceName
+		if len(selectors) == 1 {
+			workspaceID, workspaceName = selectors[0].ID, selectors[0].Name
 		}
-		klog.Infof("Importing image %s. Please wait...", opt.ImageName)
-		jobRef, err := pvmclient.ImgClient.ImportImage(opt.ImageName, opt.ImageFilename, opt.Region,
-			opt.AccessKey, opt.SecretKey, opt.BucketName, strings.ToLower(opt.StorageType), bucketAccess)
+		pvmclient, err := client.NewPVMClientWithEnv(pvsClient, workspaceID, workspaceName, pkg.Options.Environment)
 		if err != nil {
 			return err
 		}
-		start := time.Now()
-		err = utils.PollUntil(time.Tick(2*time.Minute), time.After(opt.WatchTimeout), func() (bool, error) {
-			job, err := pvmclient.JobClient.Get(*jobRef.ID)
-			if err != nil {
-				return false, fmt.Errorf("image import job failed to complete, err: %v", err)
-			}
-			if *job.Status.State == jobStateCompleted {
-				klog.V(2).Infof("Image uploaded successfully, took %s", time.Since(start).Round(time.Second))
-				return true, nil
-			}
-			if *job.Status.State == jobStateFailed {
-				return false, fmt.Errorf("image import job failed to complete, err: %v", job.Status.Message)
-			}
-			klog.Infof("Image import is in-progress, current state: %s", *job.Status.State)
-			return false, nil
-		})
-		if err != nil {
-			return err
+
+		if manifestFlag != "" {
+			return runManifest(pvsClient, pvmclient, opt, manifestFlag)
 		}
 
-		var image = &pmodels.ImageReference{}
-		klog.Info("Retrieving image details")
+		_, err = importer.New(pvsClient, pvmclient, importOptions(opt, checksumFlag)).Run(context.Background())
+		return err
+	},
+}
 
-		if image.ImageID == nil {
-			image, err = pvmclient.ImgClient.GetImageByName(opt.ImageName)
-			if err != nil {
-				return err
-			}
+// workspaceSelectors returns the workspaces requested via the repeatable
+// --workspace-name/--workspace-id flags (names first, then IDs; repeated values are not
+// removed), falling back to the single deprecated --pvs-instance-name/--pvs-instance-id value
+// when neither flag was passed, so that single-workspace invocations are unaffected.
+func workspaceSelectors() []workspaceSelector {
+	var selectors []workspaceSelector
+	for _, name := range workspaceNames {
+		selectors = append(selectors, workspaceSelector{Name: name})
+	}
+	for _, id := range workspaceIDs {
+		selectors = append(selectors, workspaceSelector{ID: id})
+	}
+	if len(selectors) == 0 {
+		if pkg.ImageCMDOptions.WorkspaceName != "" || pkg.ImageCMDOptions.WorkspaceID != "" {
+			selectors = append(selectors, workspaceSelector{
+				Name: pkg.ImageCMDOptions.WorkspaceName,
+				ID:   pkg.ImageCMDOptions.WorkspaceID,
+			})
 		}
+	}
+	return selectors
+}
 
-		if !opt.Watch {
-			klog.Infof("Image import for %s is currently in %s state, Please check the progress in the IBM cloud UI", *image.Name, *image.State)
-			return nil
-		}
-		klog.Infof("Waiting for image %s to be active. Please wait...", opt.ImageName)
-		return utils.PollUntil(time.Tick(10*time.Second), time.After(opt.WatchTimeout), func() (bool, error) {
-			img, err := pvmclient.ImgClient.Get(*image.ImageID)
-			if err != nil {
-				return false, fmt.Errorf("failed to import the image, err: %v\n\nRun the command \"pvsadm get events -i %s\" to get more information about the failure", err, pvmclient.InstanceID)
-			}
-			if img.State == imageStateActive {
-				klog.Infof("Successfully imported the image: %s with ID: %s Total time taken: %s", *image.Name, *image.ImageID, time.Since(start).Round(time.Second))
-				return true, nil
-			}
-			klog.Infof("Waiting for image to be active. Current state: %s", img.State)
-			return false, nil
-		})
-	},
+// importOptions translates the command's flags and the shared pkg.ImageCMDOptions into the
+// importer.ImportOptions for a single image.
+func importOptions(opt pkg.ImageCMDOptionsStruct, checksum string) importer.ImportOptions {
+	return importer.ImportOptions{
+		BucketName:      opt.BucketName,
+		Region:          opt.Region,
+		ImageFilename:   opt.ImageFilename,
+		ImageName:       opt.ImageName,
+		AccessKey:       opt.AccessKey,
+		SecretKey:       opt.SecretKey,
+		Public:          opt.Public,
+		StorageType:     opt.StorageType,
+		ServiceCredName: opt.ServiceCredName,
+		Checksum:        checksum,
+		Watch:           opt.Watch,
+		WatchTimeout:    opt.WatchTimeout,
+		ImageSizeGB:     imageSizeGB,
+
+		CredentialPolicy:          credentialPolicy,
+		DeleteCredentialOnSuccess: deleteCredentialOnSuccess,
+
+		SourceType: sourceTypeFlag,
+		SourceExtras: source.Options{
+			S3Endpoint:     s3Endpoint,
+			S3SourceBucket: s3SourceBucket,
+			S3SourceObject: s3SourceObject,
+			S3AccessKey:    s3AccessKey,
+			S3SecretKey:    s3SecretKey,
+
+			GCSAccessKey:    gcsAccessKey,
+			GCSSecretKey:    gcsSecretKey,
+			GCSSourceBucket: gcsSourceBucket,
+			GCSSourceObject: gcsSourceObject,
+
+			AzureSASURL: azureSASURL,
+			SourceURL:   sourceURL,
+		},
+
+		OnEvent: eventEmitter(outputFlag),
+	}
+}
+
+// eventEmitter returns the importer.Event callback matching --output, or nil for "text" mode
+// where the existing klog lines are sufficient.
+func eventEmitter(output string) func(importer.Event) {
+	if output != "json" {
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	return func(e importer.Event) {
+		_ = enc.Encode(e)
+	}
 }
 
 func init() {
@@ -317,8 +256,9 @@ func init() {
 	Cmd.Flags().MarkDeprecated("pvs-instance-name", "pvs-instance-name is deprecated, workspace-name should be used")
 	Cmd.Flags().StringVarP(&pkg.ImageCMDOptions.WorkspaceID, "pvs-instance-id", "i", "", "PowerVS Instance ID.")
 	Cmd.Flags().MarkDeprecated("pvs-instance-id", "pvs-instance-id is deprecated, workspace-id should be used")
-	Cmd.Flags().StringVarP(&pkg.ImageCMDOptions.WorkspaceName, "workspace-name", "", "", "PowerVS Workspace name.")
-	Cmd.Flags().StringVarP(&pkg.ImageCMDOptions.WorkspaceID, "workspace-id", "", "", "PowerVS Workspace ID.")
+	Cmd.Flags().StringArrayVar(&workspaceNames, "workspace-name", nil, "PowerVS Workspace name. Repeat (or combine with --workspace-id) to fan the same import out to several workspaces, bounded by --parallelism.")
+	Cmd.Flags().StringArrayVar(&workspaceIDs, "workspace-id", nil, "PowerVS Workspace ID. Repeat (or combine with --workspace-name) to fan the same import out to several workspaces, bounded by --parallelism.")
+	Cmd.Flags().IntVar(&parallelism, "parallelism", 4, "Maximum number of workspaces to import into concurrently when multiple --workspace-name/--workspace-id values are given.")
 	Cmd.Flags().StringVarP(&pkg.ImageCMDOptions.BucketName, "bucket", "b", "", "Cloud Object Storage bucket name.")
 	Cmd.Flags().StringVarP(&pkg.ImageCMDOptions.COSInstanceName, "cos-instance-name", "s", "", "Cloud Object Storage instance name.")
 	// TODO It's deprecated and will be removed in a future release
@@ -337,11 +277,28 @@ func init() {
 																						Tier 3            | 3 IOPS/GB
 																						Fixed IOPS/Tier5k |	5000 IOPS regardless of size
 																						Note: The use of fixed IOPS is limited to volumes with a size of 200 GB or less, which is the break even size with Tier 0 (200 GB @ 25 IOPS/GB = 5000 IOPS).`)
-	Cmd.Flags().StringVar(&pkg.ImageCMDOptions.ServiceCredName, "cos-service-cred", "", "IBM COS Service Credential name to be auto generated(default \""+serviceCredPrefix+"-<COS Name>\")")
+	Cmd.Flags().StringVar(&pkg.ImageCMDOptions.ServiceCredName, "cos-service-cred", "", "IBM COS Service Credential name to be auto generated(default \""+source.ServiceCredPrefix+"-<COS Name>\")")
+	Cmd.Flags().StringVar(&checksumFlag, "checksum", "", "Expected checksum of the object to import, in the form sha256:<hex>. If omitted, a sidecar \"<object>.sha256\" in the same bucket is used when present.")
+	Cmd.Flags().StringVar(&manifestFlag, "manifest", "", "Path to a YAML or JSON manifest listing multiple {object, pvs-image-name, checksum, storage-type} entries to import in one invocation. Completed entries are recorded in \"<manifest>.pvsadm-state.json\" and skipped on re-run.")
+	Cmd.Flags().StringVar(&outputFlag, "output", "text", "Output format, one of [text, json]. \"json\" emits one JSON record per line for each phase transition (credentials-resolved, job-submitted, job-in-progress, job-completed, and image-active when --watch is set) in addition to the human-readable log, for consumption by automation.")
+	Cmd.Flags().Float64Var(&imageSizeGB, "image-size-gb", 0, "Declared size of the imported volume in GB, used to enforce the tier5k break-even rule (200 GB @ 25 IOPS/GB = 5000 IOPS fixed cap); --pvs-storagetype=tier5k is rejected above this size. Omit to skip the check.")
+	Cmd.Flags().StringVar(&credentialPolicy, "credential-policy", "reuse", "How to obtain the COS service credential used to read the object, one of [reuse, create, rotate]. \"reuse\" re-uses an existing HMAC-enabled credential if one exists, \"create\" always provisions a new one, \"rotate\" deletes every pvsadm-managed credential on the instance first. Has no effect when --accesskey/--secretkey or --public-bucket are used.")
+	Cmd.Flags().BoolVar(&deleteCredentialOnSuccess, "delete-credential-on-success", false, "Delete the pvsadm-managed service credential used for this import once it completes successfully, to avoid HMAC key sprawl in long-running accounts. See also \"pvsadm image credentials prune\". Cannot be combined with multiple --workspace-name/--workspace-id values.")
+
+	Cmd.Flags().StringVar(&sourceTypeFlag, "source-type", "cos", fmt.Sprintf("Where the object named by --object lives, one of %v. Non-\"cos\" sources are staged into --bucket/--object before import.", source.Names()))
+	Cmd.Flags().StringVar(&s3Endpoint, "s3-endpoint", "", "S3-compatible endpoint to read the source object from (--source-type=s3).")
+	Cmd.Flags().StringVar(&s3SourceBucket, "s3-source-bucket", "", "Bucket on --s3-endpoint holding the source object (--source-type=s3).")
+	Cmd.Flags().StringVar(&s3SourceObject, "s3-source-object", "", "Object key on --s3-endpoint to stage (--source-type=s3).")
+	Cmd.Flags().StringVar(&s3AccessKey, "s3-access-key", "", "Access key for --s3-endpoint (--source-type=s3).")
+	Cmd.Flags().StringVar(&s3SecretKey, "s3-secret-key", "", "Secret key for --s3-endpoint (--source-type=s3).")
+	Cmd.Flags().StringVar(&gcsAccessKey, "gcs-hmac-access-key", "", "GCS HMAC interop access key (--source-type=gcs).")
+	Cmd.Flags().StringVar(&gcsSecretKey, "gcs-hmac-secret", "", "GCS HMAC interop secret (--source-type=gcs).")
+	Cmd.Flags().StringVar(&gcsSourceBucket, "gcs-source-bucket", "", "GCS bucket holding the source object (--source-type=gcs).")
+	Cmd.Flags().StringVar(&gcsSourceObject, "gcs-source-object", "", "GCS object name to stage (--source-type=gcs).")
+	Cmd.Flags().StringVar(&azureSASURL, "azure-sas-url", "", "Read-only SAS URL of the source blob (--source-type=azure).")
+	Cmd.Flags().StringVar(&sourceURL, "source-url", "", "Plain HTTP(S) URL of the source object (--source-type=url).")
 
 	_ = Cmd.MarkFlagRequired("bucket")
 	_ = Cmd.MarkFlagRequired("bucket-region")
-	_ = Cmd.MarkFlagRequired("pvs-image-name")
-	_ = Cmd.MarkFlagRequired("object")
 	Cmd.Flags().SortFlags = false
 }
@@ -0,0 +1,131 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package _import
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg"
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+	"github.com/ppc64le-cloud/pvsadm/pkg/image/importer"
+)
+
+// ManifestEntry describes a single image import job within a --manifest batch run.
+type ManifestEntry struct {
+	Object       string `json:"object" yaml:"object"`
+	PVSImageName string `json:"pvs-image-name" yaml:"pvs-image-name"`
+	Checksum     string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	StorageType  string `json:"storage-type,omitempty" yaml:"storage-type,omitempty"`
+}
+
+// loadManifest reads a batch import manifest, choosing the JSON or YAML decoder based on
+// the file extension.
+func loadManifest(path string) ([]ManifestEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %q: %v", path, err)
+	}
+
+	var entries []ManifestEntry
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		err = yaml.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %q: %v", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("manifest %q does not contain any entries", path)
+	}
+	return entries, nil
+}
+
+// manifestState records which manifest entries have already completed, keyed by PowerVS image
+// name, in a sidecar "<manifest>.pvsadm-state.json" file so that re-running a manifest after a
+// partial failure skips the jobs that already finished.
+type manifestState struct {
+	path      string
+	Completed map[string]string `json:"completed"` // pvs-image-name -> jobRef ID
+}
+
+func loadManifestState(manifestPath string) (*manifestState, error) {
+	state := &manifestState{path: manifestPath + ".pvsadm-state.json", Completed: map[string]string{}}
+	data, err := os.ReadFile(state.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file %q: %v", state.path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %q: %v", state.path, err)
+	}
+	return state, nil
+}
+
+func (s *manifestState) markCompleted(pvsImageName, jobRef string) error {
+	s.Completed[pvsImageName] = jobRef
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// runManifest imports every entry of a --manifest file that has not already completed in a
+// previous run, recording each successful jobRef in the manifest state file as it goes so the
+// command can be safely re-run after a partial failure.
+func runManifest(pvsClient *client.Client, pvmclient *client.PVMClient, opt pkg.ImageCMDOptionsStruct, manifestPath string) error {
+	entries, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	state, err := loadManifestState(manifestPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if jobRef, ok := state.Completed[entry.PVSImageName]; ok {
+			klog.Infof("Skipping %q, already completed in a previous run (jobRef: %s)", entry.PVSImageName, jobRef)
+			continue
+		}
+
+		entryOpt := opt
+		entryOpt.ImageFilename = entry.Object
+		entryOpt.ImageName = entry.PVSImageName
+		if entry.StorageType != "" {
+			entryOpt.StorageType = entry.StorageType
+		}
+
+		klog.Infof("Importing manifest entry %q (object: %s)", entry.PVSImageName, entry.Object)
+		jobRef, err := importer.New(pvsClient, pvmclient, importOptions(entryOpt, entry.Checksum)).Run(context.Background())
+		if err != nil {
+			return fmt.Errorf("manifest entry %q failed: %v", entry.PVSImageName, err)
+		}
+		if err := state.markCompleted(entry.PVSImageName, *jobRef.ID); err != nil {
+			return fmt.Errorf("failed to record completed state for %q: %v", entry.PVSImageName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package _import
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg"
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+	"github.com/ppc64le-cloud/pvsadm/pkg/image/importer"
+)
+
+// workspaceSelector names a single target workspace for a fan-out import, by name or by ID.
+type workspaceSelector struct {
+	Name string
+	ID   string
+}
+
+func (s workspaceSelector) String() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return s.ID
+}
+
+// runParallel imports the same object into every selected workspace, running up to
+// parallelism imports at a time. The source object is staged and its COS credentials
+// resolved exactly once, up front, rather than separately by each worker: every selector
+// names the same --bucket/--object, so letting every worker stage/download that identical
+// key concurrently would race writers and readers of one COS object against each other.
+// Every workspace is attempted regardless of earlier failures; after all workers finish, a
+// single error listing every failed workspace is returned so the command exits non-zero
+// while still completing the successful imports.
+func runParallel(pvsClient *client.Client, opt pkg.ImageCMDOptionsStruct, selectors []workspaceSelector, parallelism int) error {
+	creds, err := importer.New(pvsClient, nil, importOptions(opt, checksumFlag)).ResolveCredentials(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to resolve shared import credentials: %v", err)
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	errs := make([]error, len(selectors))
+
+	for idx, selector := range selectors {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int, selector workspaceSelector) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[idx] = importToWorkspace(pvsClient, opt, selector, creds)
+		}(idx, selector)
+	}
+	wg.Wait()
+
+	var failed []string
+	for idx, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", selectors[idx], err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("import failed for %d/%d workspace(s):\n  - %s", len(failed), len(selectors), strings.Join(failed, "\n  - "))
+	}
+	klog.Infof("Successfully imported %s into all %d workspaces", opt.ImageFilename, len(selectors))
+	return nil
+}
+
+func importToWorkspace(pvsClient *client.Client, opt pkg.ImageCMDOptionsStruct, selector workspaceSelector, creds *importer.Credentials) error {
+	klog.Infof("Starting import into workspace %q", selector)
+	pvmclient, err := client.NewPVMClientWithEnv(pvsClient, selector.ID, selector.Name, pkg.Options.Environment)
+	if err != nil {
+		return err
+	}
+
+	workspaceOpt := opt
+	workspaceOpt.WorkspaceName = selector.Name
+	workspaceOpt.WorkspaceID = selector.ID
+
+	if _, err := importer.New(pvsClient, pvmclient, importOptions(workspaceOpt, checksumFlag)).RunWithCredentials(creds); err != nil {
+		return err
+	}
+	klog.Infof("Completed import into workspace %q", selector)
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"k8s.io/klog/v2"
+)
+
+// checksumSidecarSuffix is appended to the object name to look up a sidecar checksum file
+// when the user does not pass --checksum explicitly.
+const checksumSidecarSuffix = ".sha256"
+
+// newCOSSession builds a plain S3-compatible session against the public COS endpoint for the
+// given region, used only to verify the object checksum before the import job is submitted.
+// HMAC credentials are used when available, falling back to anonymous access for public buckets.
+func newCOSSession(region, accessKey, secretKey string) (*s3.S3, error) {
+	creds := credentials.AnonymousCredentials
+	if accessKey != "" && secretKey != "" {
+		creds = credentials.NewStaticCredentials(accessKey, secretKey, "")
+	}
+	sess, err := session.NewSession(aws.NewConfig().
+		WithEndpoint(fmt.Sprintf("s3.%s.cloud-object-storage.appdomain.cloud", region)).
+		WithRegion(region).
+		WithS3ForcePathStyle(true).
+		WithCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create COS session for checksum verification: %v", err)
+	}
+	return s3.New(sess), nil
+}
+
+// verifyObjectChecksum downloads the COS object and confirms its SHA256 digest matches
+// expected, which may be prefixed with "sha256:". If expected is empty, the sidecar
+// "<object>.sha256" is looked up in the same bucket; if the sidecar genuinely doesn't exist,
+// verification is skipped with a warning so existing imports without a recorded checksum keep
+// working. Any other error reading the sidecar (auth, throttling, network) is returned instead
+// of being silently treated as "no sidecar", so a transient COS error can't disable the check.
+func verifyObjectChecksum(sess *s3.S3, bucket, object, expected string) error {
+	if expected == "" {
+		sidecar, err := readSidecarChecksum(sess, bucket, object)
+		if err != nil {
+			if !isNotFoundErr(err) {
+				return fmt.Errorf("failed to read checksum sidecar %q: %v", object+checksumSidecarSuffix, err)
+			}
+			klog.Warningf("no --checksum supplied and no sidecar %s%s found, skipping checksum verification", object, checksumSidecarSuffix)
+			return nil
+		}
+		expected = sidecar
+	}
+	expected = strings.TrimPrefix(expected, "sha256:")
+
+	klog.Infof("Verifying checksum of %q before submitting the import job", object)
+	out, err := sess.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download %q for checksum verification: %v", object, err)
+	}
+	defer out.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, out.Body); err != nil {
+		return fmt.Errorf("failed to read %q while computing checksum: %v", object, err)
+	}
+	actual := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %q: expected sha256:%s, got sha256:%s", object, expected, actual)
+	}
+	klog.Infof("Checksum verified for %q", object)
+	return nil
+}
+
+// isNotFoundErr reports whether err is the S3/COS "no such key" error, as opposed to some other
+// failure (auth, throttling, network) that happens to occur while fetching an object.
+func isNotFoundErr(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch aerr.Code() {
+	case s3.ErrCodeNoSuchKey, "NotFound":
+		return true
+	default:
+		return false
+	}
+}
+
+func readSidecarChecksum(sess *s3.S3, bucket, object string) (string, error) {
+	out, err := sess.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object + checksumSidecarSuffix),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return "", err
+	}
+	// sidecar files may be "<hex>" or "<hex>  <filename>" (sha256sum(1) format)
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum sidecar %q", object+checksumSidecarSuffix)
+	}
+	return fields[0], nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import "time"
+
+// Phase values reported on Event.Phase, in the order an import progresses through them.
+const (
+	PhaseCredentialsResolved = "credentials-resolved"
+	PhaseJobSubmitted        = "job-submitted"
+	PhaseJobInProgress       = "job-in-progress"
+	PhaseJobCompleted        = "job-completed"
+	PhaseImageActive         = "image-active"
+)
+
+// Event reports a single phase transition of an import, so that callers embedding this
+// package (or the `pvsadm image import --output json` CLI mode) can track progress without
+// scraping human-readable log lines.
+type Event struct {
+	Phase          string `json:"phase"`
+	State          string `json:"state,omitempty"`
+	JobID          string `json:"jobId,omitempty"`
+	ImageID        string `json:"imageId,omitempty"`
+	StorageType    string `json:"storageType,omitempty"`
+	ElapsedSeconds int    `json:"elapsedSeconds"`
+	Message        string `json:"message,omitempty"`
+}
+
+// emit reports an event through opts.OnEvent, if the caller registered one, with
+// ElapsedSeconds measured since the Importer was created.
+func (i *Importer) emit(phase, state, jobID, imageID, message string) {
+	if i.opts.OnEvent == nil {
+		return
+	}
+	i.opts.OnEvent(Event{
+		Phase:          phase,
+		State:          state,
+		JobID:          jobID,
+		ImageID:        imageID,
+		StorageType:    i.opts.StorageType,
+		ElapsedSeconds: int(time.Since(i.start).Round(time.Second).Seconds()),
+		Message:        message,
+	})
+}
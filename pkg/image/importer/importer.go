@@ -0,0 +1,312 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importer implements the PowerVS image import workflow as a library, so that it can
+// be embedded by automation (CAPI-IBMCloud controllers, CSI driver integration tests, ...)
+// instead of only being reachable through the `pvsadm image import` CLI.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	pmodels "github.com/IBM-Cloud/power-go-client/power/models"
+	"k8s.io/klog/v2"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+	"github.com/ppc64le-cloud/pvsadm/pkg/image/source"
+	"github.com/ppc64le-cloud/pvsadm/pkg/storage"
+	"github.com/ppc64le-cloud/pvsadm/pkg/utils"
+)
+
+const (
+	imageStateActive  = "active"
+	jobStateCompleted = "completed"
+	jobStateFailed    = "failed"
+)
+
+// ImportOptions configures a single image import.
+type ImportOptions struct {
+	BucketName      string
+	Region          string
+	ImageFilename   string
+	ImageName       string
+	AccessKey       string
+	SecretKey       string
+	Public          bool
+	StorageType     string
+	ServiceCredName string
+	Checksum        string
+	Watch           bool
+	WatchTimeout    time.Duration
+
+	// ImageSizeGB is the declared or detected size of the object being imported, used to
+	// enforce the tier5k break-even rule in VerifyStorageTier. A value of 0 skips the check.
+	ImageSizeGB float64
+
+	// CredentialPolicy controls how the COS service credential used to read the object is
+	// obtained: "reuse" (default), "create", or "rotate". See source.Options.CredentialPolicy.
+	CredentialPolicy string
+	// DeleteCredentialOnSuccess deletes the pvsadm-managed service credential used for this
+	// import once the image is fully imported, so long-running accounts don't accumulate
+	// HMAC keys. It has no effect on user-supplied or public-bucket credentials.
+	DeleteCredentialOnSuccess bool
+
+	// SourceType selects the source.Backend that resolves/stages ImageFilename into
+	// BucketName; it defaults to "cos" (the object already lives there) when empty.
+	SourceType   string
+	SourceExtras source.Options
+
+	// OnEvent, if set, is called as the import progresses through each Phase, in addition to
+	// the existing klog output. It must return quickly; slow callers should buffer.
+	OnEvent func(Event)
+}
+
+// Credentials are the result of resolving where the object lives and how PowerVS should
+// read it.
+type Credentials struct {
+	// SourceURL is set only when the object was staged from a non-COS backend.
+	SourceURL    string
+	AccessKey    string
+	SecretKey    string
+	BucketAccess string
+	// CredentialID is the resource key ID of the pvsadm-managed service credential used for
+	// this import, set only when DeleteCredentialOnSuccess requested it be resolved.
+	CredentialID string
+}
+
+// Importer runs the PowerVS image import workflow against a single workspace.
+type Importer struct {
+	pvsClient *client.Client
+	pvmClient *client.PVMClient
+	opts      ImportOptions
+	start     time.Time
+}
+
+// New returns an Importer bound to the given PowerVS workspace client.
+func New(pvsClient *client.Client, pvmClient *client.PVMClient, opts ImportOptions) *Importer {
+	return &Importer{pvsClient: pvsClient, pvmClient: pvmClient, opts: opts, start: time.Now()}
+}
+
+// VerifyStorageTier confirms the workspace supports the requested storage tier.
+func (i *Importer) VerifyStorageTier() error {
+	validStorageType := []string{"tier3", "tier1", "tier0", "tier5k"}
+	if !utils.Contains(validStorageType, i.opts.StorageType) {
+		return fmt.Errorf("provide valid StorageType. Allowable values are %v", validStorageType)
+	}
+
+	storageTiers, err := i.pvmClient.StorageTierClient.GetAll()
+	if err != nil {
+		return fmt.Errorf("an error occured while retriving the Storage tier availability. err:%v", err)
+	}
+	for _, storageTier := range storageTiers {
+		if storageTier.Name == i.opts.StorageType && *storageTier.State == "inactive" {
+			return fmt.Errorf("the requested storage tier is not available in the provided cloud instance. Please retry with a different tier")
+		}
+	}
+
+	if i.opts.ImageSizeGB > 0 {
+		advisor := storage.NewStorageTierAdvisor()
+		if i.opts.StorageType == "tier5k" {
+			if err := advisor.ValidateTier5k(i.opts.ImageSizeGB); err != nil {
+				return err
+			}
+		}
+		if warning := advisor.WarnTier5k(i.opts.StorageType, i.opts.ImageSizeGB); warning != "" {
+			klog.Warning(warning)
+		}
+	}
+	return nil
+}
+
+// ResolveCredentials stages the source object into BucketName if required, resolves the COS
+// HMAC credentials PowerVS should use to read it, and verifies its checksum.
+func (i *Importer) ResolveCredentials(ctx context.Context) (*Credentials, error) {
+	opt := i.opts
+	sourceType := opt.SourceType
+	if sourceType == "" {
+		sourceType = "cos"
+	}
+
+	sourceOpts := opt.SourceExtras
+	sourceOpts.PVSClient = i.pvsClient
+	sourceOpts.Region = opt.Region
+	sourceOpts.BucketName = opt.BucketName
+	sourceOpts.ObjectName = opt.ImageFilename
+	sourceOpts.AccessKey = opt.AccessKey
+	sourceOpts.SecretKey = opt.SecretKey
+	sourceOpts.Public = opt.Public
+	sourceOpts.ServiceCredName = opt.ServiceCredName
+	sourceOpts.CredentialPolicy = opt.CredentialPolicy
+
+	backend, err := source.Get(sourceType, sourceOpts)
+	if err != nil {
+		return nil, err
+	}
+	sourceURL, accessKey, secretKey, bucketAccess, err := backend.Resolve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve source %q: %v", backend.Name(), err)
+	}
+	if sourceURL != "" {
+		klog.Infof("Staged %s into COS bucket %q as %q", sourceURL, opt.BucketName, opt.ImageFilename)
+	}
+
+	cosSession, err := newCOSSession(opt.Region, accessKey, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyObjectChecksum(cosSession, opt.BucketName, opt.ImageFilename, opt.Checksum); err != nil {
+		return nil, err
+	}
+
+	creds := &Credentials{SourceURL: sourceURL, AccessKey: accessKey, SecretKey: secretKey, BucketAccess: bucketAccess}
+	if opt.DeleteCredentialOnSuccess && opt.AccessKey == "" && !opt.Public {
+		mc, err := source.LookupManagedCredentialByAccessKey(i.pvsClient, opt.BucketName, opt.Region, accessKey)
+		if err != nil {
+			klog.Warningf("could not resolve service credential for later cleanup: %v", err)
+		} else if mc != nil {
+			creds.CredentialID = mc.ID
+		}
+	}
+
+	i.emit(PhaseCredentialsResolved, "", "", "", "resolved credentials for "+opt.ImageFilename)
+	return creds, nil
+}
+
+// SubmitJob submits the PowerVS image import job and returns its job reference.
+func (i *Importer) SubmitJob(creds *Credentials) (*pmodels.JobReference, error) {
+	opt := i.opts
+	klog.Infof("Importing image %s. Please wait...", opt.ImageName)
+	jobRef, err := i.pvmClient.ImgClient.ImportImage(opt.ImageName, opt.ImageFilename, opt.Region,
+		creds.AccessKey, creds.SecretKey, opt.BucketName, strings.ToLower(opt.StorageType), creds.BucketAccess)
+	if err != nil {
+		return nil, err
+	}
+	i.emit(PhaseJobSubmitted, "", *jobRef.ID, "", "submitted import job for "+opt.ImageName)
+	return jobRef, nil
+}
+
+// WaitForJob polls the import job until it completes, fails, or WatchTimeout elapses.
+func (i *Importer) WaitForJob(jobRef *pmodels.JobReference) error {
+	start := time.Now()
+	err := utils.PollUntil(time.Tick(2*time.Minute), time.After(i.opts.WatchTimeout), func() (bool, error) {
+		job, err := i.pvmClient.JobClient.Get(*jobRef.ID)
+		if err != nil {
+			return false, fmt.Errorf("image import job failed to complete, err: %v", err)
+		}
+		if *job.Status.State == jobStateCompleted {
+			klog.V(2).Infof("Image uploaded successfully, took %s", time.Since(start).Round(time.Second))
+			return true, nil
+		}
+		if *job.Status.State == jobStateFailed {
+			return false, fmt.Errorf("image import job failed to complete, err: %v", job.Status.Message)
+		}
+		klog.Infof("Image import is in-progress, current state: %s", *job.Status.State)
+		i.emit(PhaseJobInProgress, *job.Status.State, *jobRef.ID, "", "")
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	i.emit(PhaseJobCompleted, jobStateCompleted, *jobRef.ID, "", "")
+	return nil
+}
+
+// WaitForActive looks up the imported image and, if Watch is set, waits for it to become
+// active. On success it returns the image reference looked up before any waiting; on error
+// it returns nil.
+func (i *Importer) WaitForActive() (*pmodels.ImageReference, error) {
+	opt := i.opts
+	klog.Info("Retrieving image details")
+	image, err := i.pvmClient.ImgClient.GetImageByName(opt.ImageName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opt.Watch {
+		klog.Infof("Image import for %s is currently in %s state, Please check the progress in the IBM cloud UI", *image.Name, *image.State)
+		return image, nil
+	}
+
+	start := time.Now()
+	klog.Infof("Waiting for image %s to be active. Please wait...", opt.ImageName)
+	err = utils.PollUntil(time.Tick(10*time.Second), time.After(opt.WatchTimeout), func() (bool, error) {
+		img, err := i.pvmClient.ImgClient.Get(*image.ImageID)
+		if err != nil {
+			return false, fmt.Errorf("failed to import the image, err: %v\n\nRun the command \"pvsadm get events -i %s\" to get more information about the failure", err, i.pvmClient.InstanceID)
+		}
+		if img.State == imageStateActive {
+			klog.Infof("Successfully imported the image: %s with ID: %s Total time taken: %s", *image.Name, *image.ImageID, time.Since(start).Round(time.Second))
+			return true, nil
+		}
+		klog.Infof("Waiting for image to be active. Current state: %s", img.State)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	i.emit(PhaseImageActive, imageStateActive, "", *image.ImageID, fmt.Sprintf("image %s is active", *image.Name))
+	return image, nil
+}
+
+// Run drives the full import workflow end to end, resolving credentials (staging the source
+// object first if required) before submitting the job, and returns the submitted job
+// reference.
+func (i *Importer) Run(ctx context.Context) (*pmodels.JobReference, error) {
+	if err := i.VerifyStorageTier(); err != nil {
+		return nil, err
+	}
+	creds, err := i.ResolveCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return i.runWithCredentials(creds)
+}
+
+// RunWithCredentials drives the import workflow from credentials resolved ahead of time by a
+// previous call to ResolveCredentials, instead of resolving them itself. It exists so that
+// several Importers sharing the same BucketName/ImageFilename (e.g. a `pvsadm image import`
+// fan-out across multiple workspaces) can stage the source object and resolve its COS
+// credentials exactly once, rather than each Importer racing to re-stage and re-download the
+// identical destination key concurrently.
+func (i *Importer) RunWithCredentials(creds *Credentials) (*pmodels.JobReference, error) {
+	if err := i.VerifyStorageTier(); err != nil {
+		return nil, err
+	}
+	return i.runWithCredentials(creds)
+}
+
+func (i *Importer) runWithCredentials(creds *Credentials) (*pmodels.JobReference, error) {
+	jobRef, err := i.SubmitJob(creds)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.WaitForJob(jobRef); err != nil {
+		return nil, err
+	}
+	if _, err := i.WaitForActive(); err != nil {
+		return nil, err
+	}
+
+	if i.opts.DeleteCredentialOnSuccess && creds.CredentialID != "" {
+		if err := source.DeleteManagedCredential(i.pvsClient, creds.CredentialID); err != nil {
+			klog.Warningf("import succeeded but failed to delete the service credential used: %v", err)
+		} else {
+			klog.Infof("Deleted service credential %s after successful import", creds.CredentialID)
+		}
+	}
+	return jobRef, nil
+}
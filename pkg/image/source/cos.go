@@ -0,0 +1,203 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/platform-services-go-sdk/resourcecontrollerv2"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+)
+
+const (
+	accessKeyID          = "access_key_id"
+	cosHmacKeys          = "cos_hmac_keys"
+	crnServiceRoleWriter = "crn:v1:bluemix:public:iam::::serviceRole:Writer"
+	secretAccessKey      = "secret_access_key"
+	ServiceCredPrefix    = "pvsadm-service-cred"
+)
+
+func init() {
+	Register("cos", newCOSBackend)
+}
+
+// cosBackend is the default, pre-existing behavior: the object already lives in a bucket the
+// user owns in their own IBM COS instance, named by --bucket/--object.
+type cosBackend struct {
+	opts Options
+}
+
+func newCOSBackend(opts Options) (Backend, error) {
+	return &cosBackend{opts: opts}, nil
+}
+
+func (b *cosBackend) Name() string { return "cos" }
+
+func (b *cosBackend) Resolve(ctx context.Context) (string, string, string, string, error) {
+	accessKey, secretKey, err := ResolveDestinationCredentials(b.opts)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	bucketAccess := "private"
+	if b.opts.Public {
+		bucketAccess = "public"
+	}
+	return "", accessKey, secretKey, bucketAccess, err
+}
+
+// ResolveDestinationCredentials returns the HMAC access/secret key pair PowerVS should use to
+// read opts.ObjectName out of opts.BucketName. Under opts.CredentialPolicy "reuse" (or unset)
+// it auto-creates a service credential with HMAC keys enabled only when none is already
+// usable; "create" and "rotate" always provision a new one. It is shared by every backend that
+// stages an object into the user's COS instance before import.
+func ResolveDestinationCredentials(opts Options) (string, string, error) {
+	if (opts.AccessKey != "" && opts.SecretKey != "") || opts.Public {
+		return opts.AccessKey, opts.SecretKey, nil
+	}
+
+	pvsClient := opts.PVSClient
+
+	cosInstance, err := FindCOSInstance(pvsClient, opts.BucketName, opts.Region)
+	if err != nil {
+		return "", "", err
+	}
+	klog.Infof("Identified bucket %q in service instance: %s", opts.BucketName, *cosInstance.Name)
+
+	serviceCredName := opts.ServiceCredName
+	if serviceCredName == "" {
+		serviceCredName = ServiceCredPrefix + "-" + *cosInstance.Name
+	}
+
+	var key *resourcecontrollerv2.ResourceKey
+	switch opts.CredentialPolicy {
+	case "create":
+		klog.Infof("--credential-policy=create, provisioning a new service credential: %s", serviceCredName)
+		if key, err = createNewCredentialsWithHMAC(pvsClient, *cosInstance.CRN, serviceCredName); err != nil {
+			return "", "", fmt.Errorf("error while creating HMAC credentials. err: %v", err)
+		}
+	case "rotate":
+		managed, err := ListManagedCredentials(pvsClient, cosInstance)
+		if err != nil {
+			return "", "", err
+		}
+		for _, mc := range managed {
+			klog.Infof("--credential-policy=rotate, deleting existing service credential: %s", mc.Name)
+			if err := DeleteManagedCredential(pvsClient, mc.ID); err != nil {
+				return "", "", err
+			}
+		}
+		if key, err = createNewCredentialsWithHMAC(pvsClient, *cosInstance.CRN, serviceCredName); err != nil {
+			return "", "", fmt.Errorf("error while creating HMAC credentials. err: %v", err)
+		}
+	default: // "reuse", or unset
+		if key, err = reuseOrCreateCredentialsWithHMAC(pvsClient, cosInstance, serviceCredName); err != nil {
+			return "", "", err
+		}
+	}
+
+	prop := key.Credentials.GetProperty(cosHmacKeys)
+	if prop == nil {
+		return "", "", fmt.Errorf("unable to retrieve COS HMAC keys")
+	}
+	hmacKeys, ok := prop.(map[string]interface{})
+	if !ok {
+		return "", "", fmt.Errorf("type assertion for HMAC keys failed")
+	}
+	return hmacKeys[accessKeyID].(string), hmacKeys[secretAccessKey].(string), nil
+}
+
+// reuseOrCreateCredentialsWithHMAC implements the default "reuse" credential policy: it
+// re-uses the first existing service credential that already has HMAC keys, or creates one
+// with serviceCredName if none of the existing credentials qualify.
+func reuseOrCreateCredentialsWithHMAC(pvsClient *client.Client, cosInstance *resourcecontrollerv2.ResourceInstance, serviceCredName string) (*resourcecontrollerv2.ResourceKey, error) {
+	keys, _, err := pvsClient.ResourceControllerClient.ListResourceKeysForInstance(
+		&resourcecontrollerv2.ListResourceKeysForInstanceOptions{ID: cosInstance.GUID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the resource keys for instance. err: %v", err)
+	}
+
+	// Create the service credential if none exist.
+	if len(keys.Resources) == 0 {
+		return createNewCredentialsWithHMAC(pvsClient, *cosInstance.CRN, serviceCredName)
+	}
+
+	klog.V(2).Info("Reading the existing service credential")
+	// Use the service credential already created. There may be a possibility that multiple credentials exist, but the HMAC credentials may not be present.
+	// In such case, manually re-create the credentials.
+	for _, serviceCredential := range keys.Resources {
+		key, _, err := pvsClient.ResourceControllerClient.GetResourceKey(
+			&resourcecontrollerv2.GetResourceKeyOptions{
+				ID: serviceCredential.ID,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("an error occured while retriving the resource key. err: %v", err)
+		}
+		// if the current credential has COS HMAC keys, reuse the same for importing the image
+		if prop := key.Credentials.GetProperty(cosHmacKeys); prop != nil {
+			klog.Infof("HMAC keys are available from the credential %q, re-using the same for image upload", *key.Name)
+			return key, nil
+		}
+		klog.Infof("No credentials found in the key %q.", *key.Name)
+	}
+	// None of the available service credentials have HMAC, create one with HMAC.
+	return createNewCredentialsWithHMAC(pvsClient, *cosInstance.CRN, serviceCredName)
+}
+
+// findCOSInstanceDetails retrieves the service instance in which the given bucket is present.
+func findCOSInstanceDetails(resources []resourcecontrollerv2.ResourceInstance, pvsClient *client.Client, bucketName, region string) *resourcecontrollerv2.ResourceInstance {
+	for _, resource := range resources {
+		s3client, err := client.NewS3Client(pvsClient, *resource.Name, region)
+		if err != nil {
+			klog.Warningf("cannot create a new s3 client. err: %v", err)
+			continue
+		}
+		buckets, err := s3client.S3Session.ListBuckets(nil)
+		if err != nil {
+			klog.Warningf("cannot list buckets in the resource instance. err: %v", err)
+			continue
+		}
+		for _, bucket := range buckets.Buckets {
+			if *bucket.Name == bucketName {
+				return &resource
+			}
+		}
+	}
+	return nil
+}
+
+// createNewCredentialsWithHMAC generates the service credentials in the given COS instance with HMAC keys.
+func createNewCredentialsWithHMAC(pvsClient *client.Client, cosCRN, serviceCredName string) (*resourcecontrollerv2.ResourceKey, error) {
+	klog.V(2).Infof("Auto generating COS service credentials to import image: %s", serviceCredName)
+	params := &resourcecontrollerv2.ResourceKeyPostParameters{}
+	params.SetProperty("HMAC", true)
+	key, _, err := pvsClient.ResourceControllerClient.CreateResourceKey(
+		&resourcecontrollerv2.CreateResourceKeyOptions{
+			Name:       ptr.To(serviceCredName),
+			Parameters: params,
+			Role:       ptr.To(crnServiceRoleWriter),
+			Source:     ptr.To(cosCRN),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create resource key for service instance: %v", err.Error())
+	}
+	return key, nil
+}
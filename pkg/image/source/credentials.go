@@ -0,0 +1,119 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/IBM/platform-services-go-sdk/resourcecontrollerv2"
+	"k8s.io/utils/ptr"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+	"github.com/ppc64le-cloud/pvsadm/pkg/utils"
+)
+
+// ManagedCredential describes a COS HMAC service credential that was provisioned by pvsadm,
+// identified by its ServiceCredPrefix name prefix.
+type ManagedCredential struct {
+	ID          string
+	Name        string
+	COSInstance string
+	AccessKeyID string
+}
+
+// FindCOSInstance resolves the COS service instance that owns bucketName in region. It is
+// shared by ResolveDestinationCredentials and the `pvsadm image credentials` subcommand.
+func FindCOSInstance(pvsClient *client.Client, bucketName, region string) (*resourcecontrollerv2.ResourceInstance, error) {
+	workspaces, _, err := pvsClient.ResourceControllerClient.ListResourceInstances(
+		&resourcecontrollerv2.ListResourceInstancesOptions{ResourceID: ptr.To(utils.CosResourceID)},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the resource instances: %v", err)
+	}
+	if len(workspaces.Resources) == 0 {
+		return nil, fmt.Errorf("no service instances were found")
+	}
+
+	cosInstance := findCOSInstanceDetails(workspaces.Resources, pvsClient, bucketName, region)
+	if cosInstance == nil {
+		return nil, fmt.Errorf("failed to find the COS instance for the bucket mentioned: %s", bucketName)
+	}
+	return cosInstance, nil
+}
+
+// ListManagedCredentials returns every service credential on cosInstance whose name carries
+// the ServiceCredPrefix pvsadm uses when auto-creating credentials, along with the HMAC
+// access key ID each one carries (empty if the credential has no HMAC keys).
+func ListManagedCredentials(pvsClient *client.Client, cosInstance *resourcecontrollerv2.ResourceInstance) ([]ManagedCredential, error) {
+	keys, _, err := pvsClient.ResourceControllerClient.ListResourceKeysForInstance(
+		&resourcecontrollerv2.ListResourceKeysForInstanceOptions{ID: cosInstance.GUID},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the resource keys for instance. err: %v", err)
+	}
+
+	var managed []ManagedCredential
+	for _, k := range keys.Resources {
+		if k.Name == nil || !strings.HasPrefix(*k.Name, ServiceCredPrefix) {
+			continue
+		}
+		mc := ManagedCredential{ID: *k.ID, Name: *k.Name, COSInstance: *cosInstance.Name}
+
+		full, _, err := pvsClient.ResourceControllerClient.GetResourceKey(&resourcecontrollerv2.GetResourceKeyOptions{ID: k.ID})
+		if err != nil {
+			return nil, fmt.Errorf("an error occured while retriving the resource key %q. err: %v", *k.Name, err)
+		}
+		if prop := full.Credentials.GetProperty(cosHmacKeys); prop != nil {
+			if hmacKeys, ok := prop.(map[string]interface{}); ok {
+				if id, ok := hmacKeys[accessKeyID].(string); ok {
+					mc.AccessKeyID = id
+				}
+			}
+		}
+		managed = append(managed, mc)
+	}
+	return managed, nil
+}
+
+// DeleteManagedCredential deletes a single service credential by resource key ID.
+func DeleteManagedCredential(pvsClient *client.Client, keyID string) error {
+	_, err := pvsClient.ResourceControllerClient.DeleteResourceKey(&resourcecontrollerv2.DeleteResourceKeyOptions{ID: ptr.To(keyID)})
+	if err != nil {
+		return fmt.Errorf("failed to delete service credential %q: %v", keyID, err)
+	}
+	return nil
+}
+
+// LookupManagedCredentialByAccessKey finds the pvsadm-managed credential on bucketName's COS
+// instance whose HMAC access key ID matches accessKey, for --delete-credential-on-success
+// cleanup after an import completes. It returns (nil, nil) if no managed credential matches,
+// e.g. because the access key was user-supplied rather than auto-provisioned.
+func LookupManagedCredentialByAccessKey(pvsClient *client.Client, bucketName, region, accessKey string) (*ManagedCredential, error) {
+	cosInstance, err := FindCOSInstance(pvsClient, bucketName, region)
+	if err != nil {
+		return nil, err
+	}
+	managed, err := ListManagedCredentials(pvsClient, cosInstance)
+	if err != nil {
+		return nil, err
+	}
+	for i := range managed {
+		if managed[i].AccessKeyID == accessKey {
+			return &managed[i], nil
+		}
+	}
+	return nil, nil
+}
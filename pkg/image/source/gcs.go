@@ -0,0 +1,88 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// gcsEndpoint is the GCS XML API endpoint, which speaks the S3-compatible interop protocol
+// when given a GCS HMAC access/secret key pair.
+const gcsEndpoint = "storage.googleapis.com"
+
+func init() {
+	Register("gcs", newGCSBackend)
+}
+
+// gcsBackend stages an object out of Google Cloud Storage, authenticating with a GCS HMAC
+// interop key pair rather than a Google service account, into the destination COS bucket.
+type gcsBackend struct {
+	opts Options
+}
+
+func newGCSBackend(opts Options) (Backend, error) {
+	if opts.GCSAccessKey == "" || opts.GCSSecretKey == "" {
+		return nil, fmt.Errorf("--source-type=gcs requires --gcs-hmac-access-key and --gcs-hmac-secret")
+	}
+	if opts.GCSSourceBucket == "" || opts.GCSSourceObject == "" {
+		return nil, fmt.Errorf("--source-type=gcs requires --gcs-source-bucket and --gcs-source-object")
+	}
+	return &gcsBackend{opts: opts}, nil
+}
+
+func (b *gcsBackend) Name() string { return "gcs" }
+
+func (b *gcsBackend) Resolve(ctx context.Context) (string, string, string, string, error) {
+	opts := b.opts
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithEndpoint(gcsEndpoint).
+		WithRegion(opts.Region).
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials(opts.GCSAccessKey, opts.GCSSecretKey, "")))
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to create GCS interop session: %v", err)
+	}
+
+	out, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(opts.GCSSourceBucket),
+		Key:    aws.String(opts.GCSSourceObject),
+	})
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to fetch gs://%s/%s: %v", opts.GCSSourceBucket, opts.GCSSourceObject, err)
+	}
+	defer out.Body.Close()
+
+	accessKey, secretKey, err := ResolveDestinationCredentials(opts)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	if err := stageToCOS(opts.Region, accessKey, secretKey, opts.BucketName, opts.ObjectName, out.Body); err != nil {
+		return "", "", "", "", err
+	}
+
+	bucketAccess := "private"
+	if opts.Public {
+		bucketAccess = "public"
+	}
+	return fmt.Sprintf("gs://%s/%s", opts.GCSSourceBucket, opts.GCSSourceObject), accessKey, secretKey, bucketAccess, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+func init() {
+	Register("s3", newS3Backend)
+}
+
+// s3Backend stages an object from any S3-compatible endpoint (MinIO, AWS S3, ...) into the
+// destination COS bucket so PowerVS can import it.
+type s3Backend struct {
+	opts Options
+}
+
+func newS3Backend(opts Options) (Backend, error) {
+	if opts.S3Endpoint == "" {
+		return nil, fmt.Errorf("--source-type=s3 requires --s3-endpoint")
+	}
+	if opts.S3SourceBucket == "" || opts.S3SourceObject == "" {
+		return nil, fmt.Errorf("--source-type=s3 requires --s3-source-bucket and --s3-source-object")
+	}
+	return &s3Backend{opts: opts}, nil
+}
+
+func (b *s3Backend) Name() string { return "s3" }
+
+func (b *s3Backend) Resolve(ctx context.Context) (string, string, string, string, error) {
+	opts := b.opts
+
+	sess, err := session.NewSession(aws.NewConfig().
+		WithEndpoint(opts.S3Endpoint).
+		WithRegion(opts.Region).
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials(opts.S3AccessKey, opts.S3SecretKey, "")))
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to create S3 session for %q: %v", opts.S3Endpoint, err)
+	}
+
+	out, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(opts.S3SourceBucket),
+		Key:    aws.String(opts.S3SourceObject),
+	})
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("failed to fetch s3://%s/%s from %s: %v", opts.S3SourceBucket, opts.S3SourceObject, opts.S3Endpoint, err)
+	}
+	defer out.Body.Close()
+
+	accessKey, secretKey, err := ResolveDestinationCredentials(opts)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	if err := stageToCOS(opts.Region, accessKey, secretKey, opts.BucketName, opts.ObjectName, out.Body); err != nil {
+		return "", "", "", "", err
+	}
+
+	bucketAccess := "private"
+	if opts.Public {
+		bucketAccess = "public"
+	}
+	sourceURL := fmt.Sprintf("%s/%s/%s", opts.S3Endpoint, opts.S3SourceBucket, opts.S3SourceObject)
+	return sourceURL, accessKey, secretKey, bucketAccess, nil
+}
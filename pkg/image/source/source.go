@@ -0,0 +1,109 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package source abstracts over where the OVA that `pvsadm image import` submits to PowerVS
+// actually lives. PowerVS only ever reads from an IBM Cloud Object Storage bucket, so every
+// Backend other than "cos" stages the source object into a destination COS bucket before
+// returning the HMAC credentials PowerVS needs to read it back.
+package source
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ppc64le-cloud/pvsadm/pkg/client"
+)
+
+// Backend resolves the credentials PowerVS should use to read an imported object from COS,
+// staging it from wherever it actually lives first if required.
+type Backend interface {
+	// Name identifies the backend, matching the --source-type value that selects it.
+	Name() string
+	// Resolve stages the source object into the destination COS bucket/object when required
+	// and returns the URL it staged from (empty for the "cos" backend, where the object
+	// already lives in the destination bucket), plus the HMAC access/secret key pair and
+	// bucket access level ("public"/"private") PowerVS should use to read it.
+	Resolve(ctx context.Context) (sourceURL, accessKey, secretKey, bucketAccess string, err error)
+}
+
+// Options carries every flag any registered Backend might need; each backend reads the
+// subset relevant to it and ignores the rest.
+type Options struct {
+	// PVSClient is the authenticated IBM Cloud session, used by backends that resolve or
+	// create a COS service credential for the destination bucket.
+	PVSClient *client.Client
+
+	Region          string
+	BucketName      string // destination COS bucket PowerVS imports from
+	ObjectName      string // destination COS object name
+	AccessKey       string // user-supplied destination COS HMAC access key, if any
+	SecretKey       string // user-supplied destination COS HMAC secret key, if any
+	Public          bool
+	ServiceCredName string
+	// CredentialPolicy controls how the "cos" backend (and any backend staging into COS)
+	// obtains a service credential: "reuse" (default) re-uses an existing HMAC-enabled
+	// credential if one exists, "create" always provisions a new one, and "rotate" deletes
+	// every pvsadm-managed credential on the instance first.
+	CredentialPolicy string
+
+	// S3Endpoint points the "s3" backend at an S3-compatible endpoint (MinIO, AWS S3, ...).
+	S3Endpoint     string
+	S3SourceBucket string
+	S3SourceObject string
+	S3AccessKey    string
+	S3SecretKey    string
+
+	// GCS HMAC interop keys, used by the "gcs" backend via the GCS XML API's S3-compatible
+	// interop mode (storage.googleapis.com).
+	GCSAccessKey    string
+	GCSSecretKey    string
+	GCSSourceBucket string
+	GCSSourceObject string
+
+	// AzureSASURL is a full read-only SAS URL for the blob, used by the "azure" backend.
+	AzureSASURL string
+
+	// SourceURL is a plain HTTP(S) URL, used by the "url" backend.
+	SourceURL string
+}
+
+// registry of backend constructors, keyed by --source-type.
+var registry = map[string]func(Options) (Backend, error){}
+
+// Register adds a constructor for a named backend. Each backend implementation calls this
+// from its own init().
+func Register(name string, newBackend func(Options) (Backend, error)) {
+	registry[name] = newBackend
+}
+
+// Get constructs the backend registered for the given --source-type.
+func Get(name string, opts Options) (Backend, error) {
+	newBackend, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown --source-type %q, supported types are %v", name, Names())
+	}
+	return newBackend(opts)
+}
+
+// Names returns the registered --source-type values, in a stable order for help text and
+// error messages.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
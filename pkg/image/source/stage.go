@@ -0,0 +1,49 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"k8s.io/klog/v2"
+)
+
+// stageToCOS streams src into the destination COS bucket/object, for backends whose source
+// object does not already live in the user's own COS instance.
+func stageToCOS(region, accessKey, secretKey, bucket, object string, src io.Reader) error {
+	sess, err := session.NewSession(aws.NewConfig().
+		WithEndpoint(fmt.Sprintf("s3.%s.cloud-object-storage.appdomain.cloud", region)).
+		WithRegion(region).
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, "")))
+	if err != nil {
+		return fmt.Errorf("failed to create COS session for staging: %v", err)
+	}
+
+	klog.Infof("Staging source object into COS bucket %q as %q", bucket, object)
+	if _, err := s3manager.NewUploader(sess).Upload(&s3manager.UploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(object),
+		Body:   src,
+	}); err != nil {
+		return fmt.Errorf("failed to stage object into COS bucket %q: %v", bucket, err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"context"
+	"fmt"
+)
+
+func init() {
+	Register("url", newURLBackend)
+}
+
+// urlBackend stages an object fetched from a plain HTTP(S) URL into the destination COS
+// bucket, for OVAs published on a web server with no cloud storage API in front of them.
+type urlBackend struct {
+	opts Options
+}
+
+func newURLBackend(opts Options) (Backend, error) {
+	if opts.SourceURL == "" {
+		return nil, fmt.Errorf("--source-type=url requires --source-url")
+	}
+	return &urlBackend{opts: opts}, nil
+}
+
+func (b *urlBackend) Name() string { return "url" }
+
+func (b *urlBackend) Resolve(ctx context.Context) (string, string, string, string, error) {
+	opts := b.opts
+
+	resp, err := fetchHTTP(ctx, opts.SourceURL)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	defer resp.Body.Close()
+
+	accessKey, secretKey, err := ResolveDestinationCredentials(opts)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	if err := stageToCOS(opts.Region, accessKey, secretKey, opts.BucketName, opts.ObjectName, resp.Body); err != nil {
+		return "", "", "", "", err
+	}
+
+	bucketAccess := "private"
+	if opts.Public {
+		bucketAccess = "public"
+	}
+	return opts.SourceURL, accessKey, secretKey, bucketAccess, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 IBM Corp
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storage holds PowerVS storage tier sizing rules shared across pvsadm subcommands
+// (and usable by external importers, e.g. the CSI driver) so the math isn't re-derived, or
+// re-skipped, at every call site.
+package storage
+
+import "fmt"
+
+// IOPS per GB for the size-proportional storage tiers, and the fixed IOPS granted by tier5k
+// regardless of volume size.
+const (
+	Tier0IOPSPerGB = 25
+	Tier1IOPSPerGB = 10
+	Tier3IOPSPerGB = 3
+	Tier5kIOPS     = 5000
+
+	// Tier5kBreakEvenGB is the volume size at which tier0's size-proportional IOPS equal
+	// tier5k's fixed IOPS cap (200 GB * 25 IOPS/GB = 5000 IOPS). Above this size tier0 grants
+	// more IOPS than tier5k's fixed cap, so tier5k no longer makes sense.
+	Tier5kBreakEvenGB = Tier5kIOPS / Tier0IOPSPerGB
+
+	// Tier5kWarnFraction is the fraction of Tier5kBreakEvenGB at which WarnTier5k starts
+	// advising that tier0 is catching up to tier5k's fixed cap, ahead of the hard break-even
+	// size that ValidateTier5k rejects at.
+	Tier5kWarnFraction = 0.8
+)
+
+// StorageTierAdvisor validates and recommends PowerVS storage tiers for a given volume size.
+type StorageTierAdvisor struct{}
+
+// NewStorageTierAdvisor returns a StorageTierAdvisor.
+func NewStorageTierAdvisor() *StorageTierAdvisor {
+	return &StorageTierAdvisor{}
+}
+
+// ValidateTier5k rejects tier5k for a volume larger than Tier5kBreakEvenGB, where tier0
+// already grants at least as many IOPS without the fixed-IOPS cap.
+func (a *StorageTierAdvisor) ValidateTier5k(sizeGB float64) error {
+	if sizeGB > Tier5kBreakEvenGB {
+		return fmt.Errorf("tier5k is not usable for a %.1f GB volume: fixed IOPS is capped at %d, which tier0 already matches or exceeds above %d GB", sizeGB, Tier5kIOPS, Tier5kBreakEvenGB)
+	}
+	return nil
+}
+
+// WarnTier5k returns a non-empty advisory when storageType is tier5k and sizeGB is within
+// Tier5kWarnFraction of Tier5kBreakEvenGB, i.e. tier0 is close enough to tier5k's fixed IOPS
+// cap that it's worth a second look before the volume grows past the break-even size that
+// ValidateTier5k hard-rejects at. It returns "" when no warning applies.
+func (a *StorageTierAdvisor) WarnTier5k(storageType string, sizeGB float64) string {
+	warnThreshold := Tier5kBreakEvenGB * Tier5kWarnFraction
+	if storageType != "tier5k" || sizeGB < warnThreshold {
+		return ""
+	}
+	return fmt.Sprintf("tier0 grants %.0f IOPS for a %.1f GB volume, close to tier5k's fixed %d IOPS cap; consider --pvs-storagetype=tier0 if this volume may grow past %d GB", sizeGB*Tier0IOPSPerGB, sizeGB, Tier5kIOPS, Tier5kBreakEvenGB)
+}